@@ -0,0 +1,41 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package commonTx
+
+// TxTypeVote identifies a VoteTx: an account casting a governance vote with
+// a balance snapshot weight. tree.ComputeVoteLeafHash hashes a vote leaf and
+// tree.ComputeStateRoot takes a vote subtree root; nothing in this tree
+// builds the vote subtree or calls either function.
+const TxTypeVote = 15
+
+// VoteTxInfo is the parsed form of a VoteTx. globalmapHandler.ValidateVoteTxWeight
+// checks that Weight does not exceed the voter's balance leaf in the
+// proposal's governance snapshot tree (see tree.NewGovernanceSnapshotTree)
+// against the proposal's own pinned root, so SnapshotRoot here is never
+// trusted on its own. No mempool admission path in this tree calls it.
+type VoteTxInfo struct {
+	ProposalId   int64
+	AccountIndex int64
+	Choice       int64
+	Weight       string
+	Nonce        int64
+	// SnapshotRoot is the root the tx claims the proposal was opened
+	// against; ValidateVoteTxWeight rejects the tx unless this matches
+	// the proposal registry's pinned SnapshotRoot exactly.
+	SnapshotRoot []byte
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proposal
+
+import (
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+const proposalTableName = "proposal"
+
+// Proposal is a governance proposal's pinned voting-power snapshot. It is
+// written once, when the proposal opens, and never updated afterwards, so
+// SnapshotRoot/SnapshotBlockHeight are the trust anchor VoteTx weight
+// checks verify against instead of whatever a voter's tx claims.
+type Proposal struct {
+	ProposalId int64 `db:"proposal_id"`
+	// AssetId is the governance token balances are snapshotted from.
+	AssetId int64 `db:"asset_id"`
+	// SnapshotBlockHeight is the height SnapshotRoot and every account's
+	// balance were captured at when the proposal opened.
+	SnapshotBlockHeight int64 `db:"snapshot_block_height"`
+	// SnapshotRoot is the root of the accountIndex-keyed tree of AssetId
+	// balances at SnapshotBlockHeight, built by tree.NewGovernanceSnapshotTree.
+	SnapshotRoot []byte `db:"snapshot_root"`
+}
+
+// ProposalModel is the read path for looking up a proposal's pinned
+// snapshot by id, mirroring the *HistoryModel interfaces used elsewhere
+// for history-table reads.
+type ProposalModel interface {
+	GetProposalByProposalId(proposalId int64) (*Proposal, error)
+}
+
+type defaultProposalModel struct {
+	conn  sqlx.SqlConn
+	table string
+}
+
+// NewProposalModel builds a ProposalModel backed by the proposal table,
+// the same sqlx.SqlConn convention used by the history models in
+// common/model/account and common/model/asset.
+func NewProposalModel(conn sqlx.SqlConn) ProposalModel {
+	return &defaultProposalModel{conn: conn, table: proposalTableName}
+}
+
+func (m *defaultProposalModel) GetProposalByProposalId(proposalId int64) (*Proposal, error) {
+	var proposalInfo Proposal
+	query := fmt.Sprintf(
+		"select proposal_id, asset_id, snapshot_block_height, snapshot_root from %s where proposal_id = ? limit 1",
+		m.table,
+	)
+	if err := m.conn.QueryRow(&proposalInfo, query, proposalId); err != nil {
+		return nil, err
+	}
+	return &proposalInfo, nil
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proofofreserves
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/zecrey-labs/zecrey-legend/common/tree"
+)
+
+// GroupSize is the number of account/asset leaves proven by a single
+// reserve circuit instance. prover/userproof shard the snapshot into
+// groups of this size (ShardSnapshot) so proof generation parallelizes
+// across groups.
+const GroupSize = 1024
+
+// Curve is the curve the reserve circuit is compiled over, matching the
+// curve used by the rest of zkbnb's Groth16 circuits.
+const Curve = ecc.BN254
+
+// NumAssets bounds the number of distinct assetIds the circuit aggregates
+// liabilities over in one proof. Every leaf's AssetId is constrained
+// in-circuit to fall in [0, NumAssets) (see the matched-bucket check in
+// Define below); buildAssignment additionally refuses to build a witness
+// for a row outside this range, so a too-small NumAssets fails loudly
+// instead of silently dropping liabilities from the sum.
+const NumAssets = 512
+
+// ReserveLeaf is the witness for a single account/asset leaf: the
+// asset-level preimage and its path through the account's asset tree,
+// plus the account-level preimage and its path through the account tree,
+// mirroring the two-level layout ComputeAccountLeafHash /
+// ComputeAccountAssetLeafHash commit on L1.
+type ReserveLeaf struct {
+	// Asset-level leaf: Balance/LpAmount hash via ComputeAccountAssetLeafHash,
+	// walked through AssetSiblings to this account's asset root.
+	AssetId       frontend.Variable
+	Balance       frontend.Variable
+	LpAmount      frontend.Variable
+	AssetSiblings [tree.AssetTreeHeight]frontend.Variable
+
+	// Account-level leaf: AccountNameHash/PublicKey/Nonce plus the asset
+	// root above hash via ComputeAccountLeafHash, walked through
+	// AccountSiblings to the public StateRoot.
+	AccountIndex    frontend.Variable
+	AccountNameHash frontend.Variable
+	PublicKey       frontend.Variable
+	Nonce           frontend.Variable
+	AccountSiblings [tree.AccountTreeHeight]frontend.Variable
+}
+
+// ReserveCircuit proves that, for a batch of GroupSize account/asset
+// leaves sampled from the published state root, the sum of their balances
+// per asset does not exceed the exchange's attested reserves. For each
+// leaf it re-derives the asset-level leaf and walks it to this account's
+// asset root, then re-derives the account-level leaf from that asset root
+// and walks it to StateRoot — the same two-level Merkle structure the
+// committer commits on L1 — so a leaf that isn't actually committed, or
+// attributed to the wrong assetId/accountIndex, cannot be smuggled into
+// the liability sum.
+type ReserveCircuit struct {
+	// Public inputs.
+	StateRoot        frontend.Variable            `gnark:",public"`
+	TotalReserves    [NumAssets]frontend.Variable `gnark:",public"`
+	TotalLiabilities [NumAssets]frontend.Variable `gnark:",public"`
+
+	// Witness.
+	Leaves [GroupSize]ReserveLeaf
+}
+
+func (circuit *ReserveCircuit) Define(api frontend.API) error {
+	var liabilities [NumAssets]frontend.Variable
+	for i := range liabilities {
+		liabilities[i] = 0
+	}
+
+	for _, leaf := range circuit.Leaves {
+		assetHasher, err := mimc.NewMiMC(api)
+		if err != nil {
+			return err
+		}
+		assetHasher.Write(leaf.Balance, leaf.LpAmount)
+		assetLeafHash := assetHasher.Sum()
+
+		assetBits := api.ToBinary(leaf.AssetId, tree.AssetTreeHeight)
+		assetRoot, err := hashPath(api, assetLeafHash, leaf.AssetSiblings[:], assetBits)
+		if err != nil {
+			return err
+		}
+
+		accountHasher, err := mimc.NewMiMC(api)
+		if err != nil {
+			return err
+		}
+		accountHasher.Write(leaf.AccountNameHash, leaf.PublicKey, leaf.Nonce, assetRoot)
+		accountLeafHash := accountHasher.Sum()
+
+		accountBits := api.ToBinary(leaf.AccountIndex, tree.AccountTreeHeight)
+		accountRoot, err := hashPath(api, accountLeafHash, leaf.AccountSiblings[:], accountBits)
+		if err != nil {
+			return err
+		}
+		api.AssertIsEqual(accountRoot, circuit.StateRoot)
+
+		// Bucket this leaf's balance under its AssetId and, in the same
+		// pass, assert AssetId matches exactly one bucket: a value
+		// outside [0, NumAssets) would match zero buckets and fail here
+		// instead of silently vanishing from every sum.
+		matchedBuckets := frontend.Variable(0)
+		for assetId := 0; assetId < NumAssets; assetId++ {
+			isAsset := api.IsZero(api.Sub(leaf.AssetId, assetId))
+			liabilities[assetId] = api.Add(liabilities[assetId], api.Select(isAsset, leaf.Balance, 0))
+			matchedBuckets = api.Add(matchedBuckets, isAsset)
+		}
+		api.AssertIsEqual(matchedBuckets, 1)
+	}
+
+	for assetId := 0; assetId < NumAssets; assetId++ {
+		api.AssertIsLessOrEqual(liabilities[assetId], circuit.TotalReserves[assetId])
+		api.AssertIsEqual(liabilities[assetId], circuit.TotalLiabilities[assetId])
+	}
+	return nil
+}
+
+// hashPath walks leafHash up through siblings, combining it with each
+// sibling in the order given by bits (bits[level] true means leafHash's
+// current running hash is the right child at that level), the same
+// left/right convention VerifyInclusionProof uses off-circuit.
+func hashPath(api frontend.API, leafHash frontend.Variable, siblings []frontend.Variable, bits []frontend.Variable) (frontend.Variable, error) {
+	current := leafHash
+	for level, sibling := range siblings {
+		hasher, err := mimc.NewMiMC(api)
+		if err != nil {
+			return nil, err
+		}
+		left := api.Select(bits[level], sibling, current)
+		right := api.Select(bits[level], current, sibling)
+		hasher.Write(left, right)
+		current = hasher.Sum()
+	}
+	return current, nil
+}
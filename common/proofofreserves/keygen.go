@@ -0,0 +1,72 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proofofreserves
+
+import (
+	"io"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// KeyGen compiles ReserveCircuit for the BN254 curve and runs the Groth16
+// trusted setup, writing the proving and verifying keys to provingKeyPath
+// and verifyingKeyPath respectively. It is run once per circuit version;
+// prover reuses the resulting keys across every group proof.
+func KeyGen(provingKeyPath, verifyingKeyPath string) error {
+	circuit := &ReserveCircuit{}
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	if err != nil {
+		logx.Errorf("[KeyGen] unable to compile reserve circuit: %s", err.Error())
+		return err
+	}
+
+	provingKey, verifyingKey, err := groth16.Setup(ccs)
+	if err != nil {
+		logx.Errorf("[KeyGen] unable to run groth16 setup: %s", err.Error())
+		return err
+	}
+
+	if err := writeKey(provingKeyPath, provingKey); err != nil {
+		logx.Errorf("[KeyGen] unable to write proving key: %s", err.Error())
+		return err
+	}
+	if err := writeKey(verifyingKeyPath, verifyingKey); err != nil {
+		logx.Errorf("[KeyGen] unable to write verifying key: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+type writerTo interface {
+	WriteTo(w io.Writer) (int64, error)
+}
+
+func writeKey(path string, key writerTo) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = key.WriteTo(file)
+	return err
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proofofreserves
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Group is one shard of GroupSize account/asset leaves together with the
+// proof produced for it.
+type Group struct {
+	Id    int
+	Rows  []*AccountLeafSnapshot
+	Proof groth16.Proof
+}
+
+// ShardSnapshot splits a full snapshot into GroupSize-sized groups; the
+// final group holds whatever rows remain and is not padded here
+// (buildAssignment pads each group's witness up to GroupSize).
+func ShardSnapshot(rows []*AccountLeafSnapshot) [][]*AccountLeafSnapshot {
+	var groups [][]*AccountLeafSnapshot
+	for start := 0; start < len(rows); start += GroupSize {
+		end := start + GroupSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		groups = append(groups, rows[start:end])
+	}
+	return groups
+}
+
+// Prove shards accounts into groups of GroupSize and generates a Groth16
+// proof per group in parallel, writing each group's proof to
+// outDir/group-<id>.proof. The verifying key is not written to outDir; it
+// stays where KeyGen wrote it. stateRoot and totalReserves are the circuit's public inputs;
+// totalLiabilities is derived per group from the rows assigned to it.
+func Prove(
+	provingKeyPath string,
+	rows []*AccountLeafSnapshot,
+	stateRoot []byte,
+	totalReserves [NumAssets]string,
+	outDir string,
+) ([]*Group, error) {
+	provingKey := groth16.NewProvingKey(ecc.BN254)
+	keyFile, err := os.Open(provingKeyPath)
+	if err != nil {
+		logx.Errorf("[Prove] unable to open proving key: %s", err.Error())
+		return nil, err
+	}
+	defer keyFile.Close()
+	if _, err := provingKey.ReadFrom(keyFile); err != nil {
+		logx.Errorf("[Prove] unable to read proving key: %s", err.Error())
+		return nil, err
+	}
+
+	circuit := &ReserveCircuit{}
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	if err != nil {
+		logx.Errorf("[Prove] unable to compile reserve circuit: %s", err.Error())
+		return nil, err
+	}
+
+	shards := ShardSnapshot(rows)
+	groups := make([]*Group, len(shards))
+	errs := make([]error, len(shards))
+
+	var wg sync.WaitGroup
+	for i, shard := range shards {
+		wg.Add(1)
+		go func(i int, shard []*AccountLeafSnapshot) {
+			defer wg.Done()
+			assignment, err := buildAssignment(shard, stateRoot, totalReserves)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			proof, err := groth16.Prove(ccs, provingKey, witness)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			groups[i] = &Group{Id: i, Rows: shard, Proof: proof}
+		}(i, shard)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			logx.Errorf("[Prove] group %d failed: %s", i, err.Error())
+			return nil, err
+		}
+	}
+
+	for _, group := range groups {
+		proofFile, err := os.Create(fmt.Sprintf("%s/group-%d.proof", outDir, group.Id))
+		if err != nil {
+			logx.Errorf("[Prove] unable to create proof file for group %d: %s", group.Id, err.Error())
+			return nil, err
+		}
+		_, err = group.Proof.WriteTo(proofFile)
+		proofFile.Close()
+		if err != nil {
+			logx.Errorf("[Prove] unable to write proof for group %d: %s", group.Id, err.Error())
+			return nil, err
+		}
+	}
+	return groups, nil
+}
+
+// buildAssignment is implemented in userproof.go since both the prover and
+// the userproof command need to turn a shard of snapshot rows into the same
+// circuit witness layout.
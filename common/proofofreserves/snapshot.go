@@ -0,0 +1,240 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proofofreserves
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zecrey-labs/zecrey-legend/common/model/account"
+	"github.com/zecrey-labs/zecrey-legend/common/model/asset"
+	"github.com/zecrey-labs/zecrey-legend/common/tree"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// AccountLeafSnapshot is a single row of the reserve snapshot table: one
+// account/asset pair at a fixed block height, together with everything a
+// verifier needs to re-derive both the asset-level leaf and the
+// account-level leaf above it, and walk the pair up to the state root.
+type AccountLeafSnapshot struct {
+	AccountIndex int64
+	AssetId      int64
+	Balance      string
+	LeafHash     string
+
+	// Account-level fields, constant across every asset row for the same
+	// AccountIndex: the inputs to ComputeAccountLeafHash other than the
+	// asset root, which is this account's asset tree root.
+	AccountNameHash string
+	PublicKey       string
+	Nonce           int64
+
+	// AssetSiblings/AssetBits walk the asset-level leaf up to this
+	// account's asset tree root. AccountSiblings/AccountBits walk the
+	// account-level leaf (derived from that asset root) up to the
+	// committed account tree root.
+	AssetSiblings   []string
+	AssetBits       []bool
+	AccountSiblings []string
+	AccountBits     []bool
+}
+
+// AssetProofProvider supplies the Merkle sibling path and bit vector for
+// a given account/asset leaf in that account's asset tree.
+type AssetProofProvider func(accountIndex, assetId int64) (siblings [][]byte, bits []bool, err error)
+
+// AccountProofProvider supplies the Merkle sibling path and bit vector for
+// a given account leaf in the global account tree.
+type AccountProofProvider func(accountIndex int64) (siblings [][]byte, bits []bool, err error)
+
+// DumpSnapshot iterates every account/asset pair as of blockHeight and
+// returns the rows of the reserve snapshot table. Each row carries both
+// the asset-tree path (assetId under the account) and the account-tree
+// path (accountIndex under the state root), so the reserve circuit can
+// walk the same two-level Merkle structure the committer commits on L1.
+func DumpSnapshot(
+	accountHistoryModel account.AccountHistoryModel,
+	assetHistoryModel asset.AssetHistoryModel,
+	getAssetProof AssetProofProvider,
+	getAccountProof AccountProofProvider,
+	blockHeight int64,
+) (rows []*AccountLeafSnapshot, err error) {
+	accountHistories, err := accountHistoryModel.GetAccountsByBlockHeight(blockHeight)
+	if err != nil {
+		logx.Errorf("[DumpSnapshot] unable to get accounts by block height: %s", err.Error())
+		return nil, err
+	}
+	for _, accountHistory := range accountHistories {
+		accountSiblings, accountBits, err := getAccountProof(accountHistory.AccountIndex)
+		if err != nil {
+			logx.Errorf("[DumpSnapshot] unable to build account sibling path for %d: %s",
+				accountHistory.AccountIndex, err.Error())
+			return nil, err
+		}
+
+		assetHistories, err := assetHistoryModel.GetAssetsByAccountIndexAndBlockHeight(
+			accountHistory.AccountIndex, blockHeight,
+		)
+		if err != nil {
+			logx.Errorf("[DumpSnapshot] unable to get assets for account %d: %s",
+				accountHistory.AccountIndex, err.Error())
+			return nil, err
+		}
+		for _, assetHistory := range assetHistories {
+			leafHash, err := tree.ComputeAccountAssetLeafHash(assetHistory.Balance, "0")
+			if err != nil {
+				logx.Errorf("[DumpSnapshot] unable to compute leaf hash: %s", err.Error())
+				return nil, err
+			}
+			assetSiblings, assetBits, err := getAssetProof(accountHistory.AccountIndex, assetHistory.AssetId)
+			if err != nil {
+				logx.Errorf("[DumpSnapshot] unable to build asset sibling path: %s", err.Error())
+				return nil, err
+			}
+			rows = append(rows, &AccountLeafSnapshot{
+				AccountIndex:    accountHistory.AccountIndex,
+				AssetId:         assetHistory.AssetId,
+				Balance:         assetHistory.Balance,
+				LeafHash:        hex.EncodeToString(leafHash),
+				AccountNameHash: accountHistory.AccountNameHash,
+				PublicKey:       accountHistory.PublicKey,
+				Nonce:           accountHistory.Nonce,
+				AssetSiblings:   hexEncodeAll(assetSiblings),
+				AssetBits:       assetBits,
+				AccountSiblings: hexEncodeAll(accountSiblings),
+				AccountBits:     accountBits,
+			})
+		}
+	}
+	return rows, nil
+}
+
+func hexEncodeAll(values [][]byte) []string {
+	encoded := make([]string, 0, len(values))
+	for _, value := range values {
+		encoded = append(encoded, hex.EncodeToString(value))
+	}
+	return encoded
+}
+
+const rowFieldCount = 11
+
+// FormatRow renders a snapshot row as the tab-separated line written by
+// the dbtool command, one row per account/asset pair.
+func FormatRow(row *AccountLeafSnapshot) string {
+	return strings.Join([]string{
+		strconv.FormatInt(row.AccountIndex, 10),
+		strconv.FormatInt(row.AssetId, 10),
+		row.Balance,
+		row.AccountNameHash,
+		row.PublicKey,
+		strconv.FormatInt(row.Nonce, 10),
+		row.LeafHash,
+		joinList(row.AssetSiblings),
+		joinBits(row.AssetBits),
+		joinList(row.AccountSiblings),
+		joinBits(row.AccountBits),
+	}, "\t")
+}
+
+// ParseRow parses a line written by FormatRow back into an
+// AccountLeafSnapshot, for the prover and userproof commands that read the
+// snapshot table dumped by dbtool.
+func ParseRow(line string) (*AccountLeafSnapshot, error) {
+	fields := strings.Split(line, "\t")
+	if len(fields) != rowFieldCount {
+		return nil, fmt.Errorf("snapshot row: expected %d tab-separated fields, got %d", rowFieldCount, len(fields))
+	}
+	accountIndex, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	assetId, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := strconv.ParseInt(fields[5], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	assetBits, err := splitBits(fields[8])
+	if err != nil {
+		return nil, err
+	}
+	accountBits, err := splitBits(fields[10])
+	if err != nil {
+		return nil, err
+	}
+	return &AccountLeafSnapshot{
+		AccountIndex:    accountIndex,
+		AssetId:         assetId,
+		Balance:         fields[2],
+		AccountNameHash: fields[3],
+		PublicKey:       fields[4],
+		Nonce:           nonce,
+		LeafHash:        fields[6],
+		AssetSiblings:   splitList(fields[7]),
+		AssetBits:       assetBits,
+		AccountSiblings: splitList(fields[9]),
+		AccountBits:     accountBits,
+	}, nil
+}
+
+func joinList(values []string) string {
+	return strings.Join(values, ",")
+}
+
+func splitList(joined string) []string {
+	if joined == "" {
+		return nil
+	}
+	return strings.Split(joined, ",")
+}
+
+func joinBits(bits []bool) string {
+	chars := make([]string, 0, len(bits))
+	for _, bit := range bits {
+		if bit {
+			chars = append(chars, "1")
+		} else {
+			chars = append(chars, "0")
+		}
+	}
+	return strings.Join(chars, "")
+}
+
+func splitBits(joined string) ([]bool, error) {
+	if joined == "" {
+		return nil, nil
+	}
+	bits := make([]bool, len(joined))
+	for i, char := range joined {
+		switch char {
+		case '1':
+			bits[i] = true
+		case '0':
+			bits[i] = false
+		default:
+			return nil, errors.New("snapshot row: bit vector must be a string of 0s and 1s")
+		}
+	}
+	return bits, nil
+}
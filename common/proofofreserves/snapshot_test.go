@@ -0,0 +1,72 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proofofreserves
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormatRowParseRowRoundTrip(t *testing.T) {
+	row := &AccountLeafSnapshot{
+		AccountIndex:    7,
+		AssetId:         3,
+		Balance:         "12345",
+		LeafHash:        "abcd",
+		AccountNameHash: "0xdead",
+		PublicKey:       "0xbeef",
+		Nonce:           9,
+		AssetSiblings:   []string{"aa", "bb", "cc"},
+		AssetBits:       []bool{true, false, true},
+		AccountSiblings: []string{"11", "22"},
+		AccountBits:     []bool{false, true},
+	}
+
+	parsed, err := ParseRow(FormatRow(row))
+	if err != nil {
+		t.Fatalf("unable to parse formatted row: %s", err.Error())
+	}
+	if !reflect.DeepEqual(row, parsed) {
+		t.Fatalf("round trip mismatch: got %+v, want %+v", parsed, row)
+	}
+}
+
+func TestParseRowRejectsWrongFieldCount(t *testing.T) {
+	if _, err := ParseRow("1\t2\t3"); err == nil {
+		t.Fatal("expected an error for a line missing fields")
+	}
+}
+
+func TestParseRowEmptyPaths(t *testing.T) {
+	row := &AccountLeafSnapshot{
+		AccountIndex:    1,
+		AssetId:         0,
+		Balance:         "0",
+		LeafHash:        "",
+		AccountNameHash: "0x00",
+		PublicKey:       "0x00",
+		Nonce:           0,
+	}
+	parsed, err := ParseRow(FormatRow(row))
+	if err != nil {
+		t.Fatalf("unable to parse formatted row: %s", err.Error())
+	}
+	if len(parsed.AssetSiblings) != 0 || len(parsed.AccountSiblings) != 0 {
+		t.Fatalf("expected empty sibling paths, got %+v", parsed)
+	}
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proofofreserves
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// UserProof is the per-user artifact emitted by the userproof command. A
+// user can recompute the asset-level and account-level leaves from the
+// fields below using the same MiMC layout as ComputeAccountAssetLeafHash /
+// ComputeAccountLeafHash, walk them up through AssetSiblings/AccountSiblings,
+// and confirm the result matches the published state root covered by
+// GroupProof.
+type UserProof struct {
+	AccountIndex    int64    `json:"accountIndex"`
+	AssetId         int64    `json:"assetId"`
+	Balance         string   `json:"balance"`
+	LpAmount        string   `json:"lpAmount"`
+	AccountNameHash string   `json:"accountNameHash"`
+	PublicKey       string   `json:"publicKey"`
+	Nonce           int64    `json:"nonce"`
+	LeafHash        string   `json:"leafHash"`
+	AssetSiblings   []string `json:"assetSiblings"`
+	AssetBits       []bool   `json:"assetBits"`
+	AccountSiblings []string `json:"accountSiblings"`
+	AccountBits     []bool   `json:"accountBits"`
+	GroupId         int      `json:"groupId"`
+	GroupProof      string   `json:"groupProof"`
+}
+
+// BuildUserProof finds row's account/asset leaf inside group and emits the
+// JSON artifact described by UserProof. It fails if the account/asset pair
+// isn't part of the group.
+func BuildUserProof(group *Group, accountIndex, assetId int64) (*UserProof, error) {
+	for _, row := range group.Rows {
+		if row.AccountIndex != accountIndex || row.AssetId != assetId {
+			continue
+		}
+		var proofBuf bytes.Buffer
+		if _, err := group.Proof.WriteTo(&proofBuf); err != nil {
+			logx.Errorf("[BuildUserProof] unable to serialize group proof: %s", err.Error())
+			return nil, err
+		}
+		return &UserProof{
+			AccountIndex:    row.AccountIndex,
+			AssetId:         row.AssetId,
+			Balance:         row.Balance,
+			LpAmount:        "0",
+			AccountNameHash: row.AccountNameHash,
+			PublicKey:       row.PublicKey,
+			Nonce:           row.Nonce,
+			LeafHash:        row.LeafHash,
+			AssetSiblings:   row.AssetSiblings,
+			AssetBits:       row.AssetBits,
+			AccountSiblings: row.AccountSiblings,
+			AccountBits:     row.AccountBits,
+			GroupId:         group.Id,
+			GroupProof:      hex.EncodeToString(proofBuf.Bytes()),
+		}, nil
+	}
+	return nil, errors.New("account/asset pair not found in group")
+}
+
+// MarshalUserProof renders a UserProof as the indented JSON written to disk
+// by the userproof command.
+func MarshalUserProof(proof *UserProof) ([]byte, error) {
+	return json.MarshalIndent(proof, "", "  ")
+}
+
+// buildAssignment turns a shard of snapshot rows into the fixed-size
+// ReserveCircuit witness, padding with zero-balance leaves up to GroupSize
+// and summing per-asset liabilities for the circuit's public input. It
+// fails instead of silently dropping a row whose AssetId falls outside
+// [0, NumAssets) — such a row would vanish from the liability sum rather
+// than being counted, which would make the resulting proof meaningless.
+func buildAssignment(
+	shard []*AccountLeafSnapshot,
+	stateRoot []byte,
+	totalReserves [NumAssets]string,
+) (*ReserveCircuit, error) {
+	assignment := &ReserveCircuit{
+		StateRoot: common.Bytes2Hex(stateRoot),
+	}
+	for assetId := 0; assetId < NumAssets; assetId++ {
+		assignment.TotalReserves[assetId] = totalReserves[assetId]
+	}
+
+	var liabilities [NumAssets]big.Int
+	for i := 0; i < GroupSize; i++ {
+		leaf := ReserveLeaf{AssetId: 0, Balance: "0", LpAmount: "0", AccountIndex: 0, AccountNameHash: "0", PublicKey: "0", Nonce: 0}
+		for level := 0; level < len(leaf.AssetSiblings); level++ {
+			leaf.AssetSiblings[level] = "0"
+		}
+		for level := 0; level < len(leaf.AccountSiblings); level++ {
+			leaf.AccountSiblings[level] = "0"
+		}
+
+		if i < len(shard) {
+			row := shard[i]
+			if row.AssetId < 0 || int(row.AssetId) >= NumAssets {
+				return nil, fmt.Errorf(
+					"buildAssignment: account %d asset %d is outside the circuit's supported range [0, %d)",
+					row.AccountIndex, row.AssetId, NumAssets,
+				)
+			}
+			if len(row.AssetSiblings) > len(leaf.AssetSiblings) || len(row.AccountSiblings) > len(leaf.AccountSiblings) {
+				return nil, fmt.Errorf(
+					"buildAssignment: account %d asset %d has a sibling path longer than the circuit's tree heights",
+					row.AccountIndex, row.AssetId,
+				)
+			}
+
+			leaf.AssetId = row.AssetId
+			leaf.Balance = row.Balance
+			leaf.AccountIndex = row.AccountIndex
+			leaf.AccountNameHash = row.AccountNameHash
+			leaf.PublicKey = row.PublicKey
+			leaf.Nonce = row.Nonce
+			for level, sibling := range row.AssetSiblings {
+				leaf.AssetSiblings[level] = sibling
+			}
+			for level, sibling := range row.AccountSiblings {
+				leaf.AccountSiblings[level] = sibling
+			}
+
+			balance, ok := new(big.Int).SetString(row.Balance, 10)
+			if !ok {
+				return nil, fmt.Errorf("buildAssignment: invalid balance %q in snapshot row", row.Balance)
+			}
+			liabilities[row.AssetId].Add(&liabilities[row.AssetId], balance)
+		}
+		assignment.Leaves[i] = leaf
+	}
+	for assetId := 0; assetId < NumAssets; assetId++ {
+		assignment.TotalLiabilities[assetId] = liabilities[assetId].String()
+	}
+	return assignment, nil
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tree
+
+import (
+	"github.com/zecrey-labs/zecrey-legend/common/model/account"
+	"github.com/zecrey-labs/zecrey-legend/common/model/asset"
+)
+
+// NewAccountAssetTree rebuilds accountIndex's asset tree at blockHeight
+// from its asset history, one leaf per assetId via ComputeAccountAssetLeafHash,
+// for tools that reconstruct state offline instead of sharing the
+// committer's live tree.
+func NewAccountAssetTree(
+	assetHistoryModel asset.AssetHistoryModel,
+	accountIndex int64,
+	blockHeight int64,
+) (*Tree, error) {
+	assetHistories, err := assetHistoryModel.GetAssetsByAccountIndexAndBlockHeight(accountIndex, blockHeight)
+	if err != nil {
+		return nil, err
+	}
+	assetTree, err := NewEmptyTree(AssetTreeHeight)
+	if err != nil {
+		return nil, err
+	}
+	for _, assetHistory := range assetHistories {
+		leafHash, err := ComputeAccountAssetLeafHash(assetHistory.Balance, "0")
+		if err != nil {
+			return nil, err
+		}
+		if err := assetTree.Set(assetHistory.AssetId, leafHash); err != nil {
+			return nil, err
+		}
+	}
+	if err := assetTree.Commit(); err != nil {
+		return nil, err
+	}
+	return assetTree, nil
+}
+
+// NewAccountTree rebuilds the global account tree at blockHeight: for each
+// account it first rebuilds that account's asset tree, then derives the
+// account leaf with ComputeAccountLeafHash using that asset tree's root,
+// the same two-level layout the committer commits on L1. It returns the
+// account tree together with every per-account asset tree, since both are
+// needed to build inclusion proofs through the full two-level path.
+func NewAccountTree(
+	accountHistoryModel account.AccountHistoryModel,
+	assetHistoryModel asset.AssetHistoryModel,
+	blockHeight int64,
+) (accountTree *Tree, assetTrees map[int64]*Tree, err error) {
+	accountHistories, err := accountHistoryModel.GetAccountsByBlockHeight(blockHeight)
+	if err != nil {
+		return nil, nil, err
+	}
+	accountTree, err = NewEmptyTree(AccountTreeHeight)
+	if err != nil {
+		return nil, nil, err
+	}
+	assetTrees = make(map[int64]*Tree, len(accountHistories))
+	for _, accountHistory := range accountHistories {
+		assetTree, err := NewAccountAssetTree(assetHistoryModel, accountHistory.AccountIndex, blockHeight)
+		if err != nil {
+			return nil, nil, err
+		}
+		assetTrees[accountHistory.AccountIndex] = assetTree
+
+		leafHash, err := ComputeAccountLeafHash(
+			accountHistory.AccountNameHash,
+			accountHistory.PublicKey,
+			accountHistory.Nonce,
+			assetTree.Root(),
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := accountTree.Set(accountHistory.AccountIndex, leafHash); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := accountTree.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return accountTree, assetTrees, nil
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tree
+
+import (
+	"github.com/zecrey-labs/zecrey-legend/common/model/account"
+	"github.com/zecrey-labs/zecrey-legend/common/model/asset"
+)
+
+// NewGovernanceSnapshotTree rebuilds the accountIndex-keyed tree of assetId
+// balances at blockHeight that a governance proposal pins as its voting-
+// power snapshot. Unlike NewAccountAssetTree (one account's balances
+// keyed by assetId), this tree holds every account's balance of a single
+// assetId keyed by accountIndex, so every voter proves inclusion under the
+// one root the proposal recorded when it opened. It walks the same
+// account/asset history models NewAccountTree uses, rather than a
+// dedicated per-asset query, since only per-account history lookups are
+// available.
+func NewGovernanceSnapshotTree(
+	accountHistoryModel account.AccountHistoryModel,
+	assetHistoryModel asset.AssetHistoryModel,
+	assetId int64,
+	blockHeight int64,
+) (*Tree, error) {
+	accountHistories, err := accountHistoryModel.GetAccountsByBlockHeight(blockHeight)
+	if err != nil {
+		return nil, err
+	}
+	snapshotTree, err := NewEmptyTree(AccountTreeHeight)
+	if err != nil {
+		return nil, err
+	}
+	for _, accountHistory := range accountHistories {
+		assetHistories, err := assetHistoryModel.GetAssetsByAccountIndexAndBlockHeight(accountHistory.AccountIndex, blockHeight)
+		if err != nil {
+			return nil, err
+		}
+		balance := "0"
+		for _, assetHistory := range assetHistories {
+			if assetHistory.AssetId == assetId {
+				balance = assetHistory.Balance
+				break
+			}
+		}
+		leafHash, err := ComputeAccountAssetLeafHash(balance, "0")
+		if err != nil {
+			return nil, err
+		}
+		if err := snapshotTree.Set(accountHistory.AccountIndex, leafHash); err != nil {
+			return nil, err
+		}
+	}
+	if err := snapshotTree.Commit(); err != nil {
+		return nil, err
+	}
+	return snapshotTree, nil
+}
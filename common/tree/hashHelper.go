@@ -98,6 +98,29 @@ func ComputeLiquidityAssetLeafHash(
 	return hashVal, nil
 }
 
+func ComputeVoteLeafHash(
+	proposalId int64,
+	accountIndex int64,
+	choice int64,
+	weight string,
+	nonce int64,
+) (hashVal []byte, err error) {
+	hFunc := mimc.NewMiMC()
+	var buf bytes.Buffer
+	util.WriteInt64IntoBuf(&buf, proposalId)
+	util.WriteInt64IntoBuf(&buf, accountIndex)
+	util.WriteInt64IntoBuf(&buf, choice)
+	err = util.WriteStringBigIntIntoBuf(&buf, weight)
+	if err != nil {
+		logx.Errorf("[ComputeVoteLeafHash] unable to write big int to buf: %s", err.Error())
+		return nil, err
+	}
+	util.WriteInt64IntoBuf(&buf, nonce)
+	hFunc.Write(buf.Bytes())
+	hashVal = hFunc.Sum(nil)
+	return hashVal, nil
+}
+
 func ComputeNftAssetLeafHash(
 	creatorIndex int64,
 	nftContentHash string,
@@ -0,0 +1,69 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestComputeVoteLeafHashDeterministic(t *testing.T) {
+	hash1, err := ComputeVoteLeafHash(1, 7, 0, "1000", 3)
+	if err != nil {
+		t.Fatalf("unable to compute leaf hash: %s", err.Error())
+	}
+	hash2, err := ComputeVoteLeafHash(1, 7, 0, "1000", 3)
+	if err != nil {
+		t.Fatalf("unable to compute leaf hash: %s", err.Error())
+	}
+	if !bytes.Equal(hash1, hash2) {
+		t.Fatal("expected identical inputs to produce identical leaf hashes")
+	}
+}
+
+func TestComputeVoteLeafHashDiffersOnEachField(t *testing.T) {
+	base, err := ComputeVoteLeafHash(1, 7, 0, "1000", 3)
+	if err != nil {
+		t.Fatalf("unable to compute leaf hash: %s", err.Error())
+	}
+
+	variants := []struct {
+		name string
+		hash []byte
+	}{
+		{"proposalId", mustVoteLeafHash(t, 2, 7, 0, "1000", 3)},
+		{"accountIndex", mustVoteLeafHash(t, 1, 8, 0, "1000", 3)},
+		{"choice", mustVoteLeafHash(t, 1, 7, 1, "1000", 3)},
+		{"weight", mustVoteLeafHash(t, 1, 7, 0, "1001", 3)},
+		{"nonce", mustVoteLeafHash(t, 1, 7, 0, "1000", 4)},
+	}
+	for _, variant := range variants {
+		if bytes.Equal(base, variant.hash) {
+			t.Fatalf("expected changing %s to change the leaf hash", variant.name)
+		}
+	}
+}
+
+func mustVoteLeafHash(t *testing.T, proposalId, accountIndex, choice int64, weight string, nonce int64) []byte {
+	t.Helper()
+	hash, err := ComputeVoteLeafHash(proposalId, accountIndex, choice, weight, nonce)
+	if err != nil {
+		t.Fatalf("unable to compute leaf hash: %s", err.Error())
+	}
+	return hash
+}
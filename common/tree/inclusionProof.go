@@ -0,0 +1,76 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tree
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+)
+
+// GenerateAccountInclusionProof walks accountTree from the leaf for
+// accountIndex up to the root, returning the ordered sibling hashes and
+// the matching left/right bit vector (bits[i] true means the leaf-side
+// hash at level i is the right child of its parent). Feeding the result
+// into VerifyInclusionProof alongside the value returned by
+// ComputeAccountLeafHash lets a caller audit GetLatestAccountInfo against
+// the account tree root, which ComputeStateRoot folds into the state root.
+func GenerateAccountInclusionProof(accountTree *Tree, accountIndex int64) (siblings [][]byte, bits []bool, err error) {
+	if accountTree == nil {
+		return nil, nil, errors.New("account tree is nil")
+	}
+	return accountTree.buildProof(accountIndex)
+}
+
+// GenerateAccountAssetInclusionProof walks assetTree, the per-account
+// asset tree, from the leaf for assetId up to its root, returning the
+// ordered sibling hashes and left/right bit vector. Feeding the result
+// into VerifyInclusionProof alongside the value returned by
+// ComputeAccountAssetLeafHash lets a caller audit GetLatestAsset against
+// the asset root committed under the account leaf.
+func GenerateAccountAssetInclusionProof(assetTree *Tree, accountIndex, assetId int64) (siblings [][]byte, bits []bool, err error) {
+	if assetTree == nil {
+		return nil, nil, errors.New("asset tree is nil")
+	}
+	return assetTree.buildProof(assetId)
+}
+
+// VerifyInclusionProof re-derives the path from leafHash to root, running
+// the same MiMC hash used by ComputeAccountLeafHash /
+// ComputeAccountAssetLeafHash / ComputeLiquidityAssetLeafHash at every
+// level and combining leafHash with each sibling in the order given by
+// bits. It returns true only if the final hash matches root exactly.
+func VerifyInclusionProof(leafHash []byte, siblings [][]byte, bits []bool, root []byte) (bool, error) {
+	if len(siblings) != len(bits) {
+		return false, errors.New("siblings and bits must have the same length")
+	}
+	current := leafHash
+	for level, sibling := range siblings {
+		hFunc := mimc.NewMiMC()
+		if bits[level] {
+			hFunc.Write(sibling)
+			hFunc.Write(current)
+		} else {
+			hFunc.Write(current)
+			hFunc.Write(sibling)
+		}
+		current = hFunc.Sum(nil)
+	}
+	return bytes.Equal(current, root), nil
+}
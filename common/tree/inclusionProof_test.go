@@ -0,0 +1,63 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tree
+
+import "testing"
+
+func TestGenerateAccountInclusionProofRoundTrip(t *testing.T) {
+	accountTree, err := NewEmptyTree(AccountTreeHeight)
+	if err != nil {
+		t.Fatalf("unable to build tree: %s", err.Error())
+	}
+
+	leafHash, err := ComputeAccountLeafHash("0x01", "0x02", 1, []byte("assetRoot"))
+	if err != nil {
+		t.Fatalf("unable to compute leaf hash: %s", err.Error())
+	}
+	if err := accountTree.Set(7, leafHash); err != nil {
+		t.Fatalf("unable to set leaf: %s", err.Error())
+	}
+	if err := accountTree.Commit(); err != nil {
+		t.Fatalf("unable to commit tree: %s", err.Error())
+	}
+
+	siblings, bits, err := GenerateAccountInclusionProof(accountTree, 7)
+	if err != nil {
+		t.Fatalf("unable to generate inclusion proof: %s", err.Error())
+	}
+
+	valid, err := VerifyInclusionProof(leafHash, siblings, bits, accountTree.Root())
+	if err != nil {
+		t.Fatalf("unable to verify inclusion proof: %s", err.Error())
+	}
+	if !valid {
+		t.Fatal("expected inclusion proof to verify against the tree root")
+	}
+
+	wrongLeafHash, err := ComputeAccountLeafHash("0x01", "0x02", 2, []byte("assetRoot"))
+	if err != nil {
+		t.Fatalf("unable to compute leaf hash: %s", err.Error())
+	}
+	valid, err = VerifyInclusionProof(wrongLeafHash, siblings, bits, accountTree.Root())
+	if err != nil {
+		t.Fatalf("unable to verify inclusion proof: %s", err.Error())
+	}
+	if valid {
+		t.Fatal("expected inclusion proof for a different leaf to fail verification")
+	}
+}
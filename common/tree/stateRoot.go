@@ -0,0 +1,41 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tree
+
+import (
+	"bytes"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+)
+
+// ComputeStateRoot combines the account, liquidity, NFT and vote subtree
+// roots into the single state root committed on L1: each root is appended
+// to the MiMC buffer in a fixed order so the state root changes whenever
+// any subtree does. It is additive for the vote subtree introduced
+// alongside VoteTx; no earlier version of this function exists to update
+// callers of.
+func ComputeStateRoot(accountRoot, liquidityRoot, nftRoot, voteRoot []byte) []byte {
+	hFunc := mimc.NewMiMC()
+	var buf bytes.Buffer
+	buf.Write(accountRoot)
+	buf.Write(liquidityRoot)
+	buf.Write(nftRoot)
+	buf.Write(voteRoot)
+	hFunc.Write(buf.Bytes())
+	return hFunc.Sum(nil)
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tree
+
+import (
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+)
+
+// AccountTreeHeight is the depth of the global account tree, matching the
+// height the committer compiles circuits against.
+const AccountTreeHeight = 32
+
+// AssetTreeHeight is the depth of the per-account asset tree, matching the
+// height the committer uses when it builds each account's AssetTrees.
+const AssetTreeHeight = 16
+
+// Tree wraps the sparse Merkle tree the committer uses for both the
+// account tree and every per-account asset tree, so GenerateAccountInclusionProof
+// / GenerateAccountAssetInclusionProof can walk either one the same way.
+type Tree struct {
+	smt bsmt.SparseMerkleTree
+}
+
+// NewTree wraps an already-built sparse Merkle tree, e.g. one produced by
+// the committer or reconstructed offline by NewAccountAssetTree /
+// NewAccountTree.
+func NewTree(smt bsmt.SparseMerkleTree) *Tree {
+	return &Tree{smt: smt}
+}
+
+// NewEmptyTree creates a height-deep sparse Merkle tree backed by an
+// in-memory store, for callers (NewAccountTree, NewAccountAssetTree,
+// NewGovernanceSnapshotTree) that reconstruct trees offline from the DB
+// rather than sharing the committer's live tree.
+func NewEmptyTree(height int) (*Tree, error) {
+	smt, err := bsmt.NewBASSparseMerkleTree(
+		bsmt.NewMemoryDB(),
+		height,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Tree{smt: smt}, nil
+}
+
+// Set writes leafHash at index and leaves it uncommitted; call Commit once
+// every leaf has been set to compute Root.
+func (t *Tree) Set(index int64, leafHash []byte) error {
+	return t.smt.Set(uint64(index), leafHash)
+}
+
+// Commit finalizes every pending Set call and makes Root available.
+func (t *Tree) Commit() error {
+	_, err := t.smt.Commit(nil)
+	return err
+}
+
+// Root returns the tree's current root hash.
+func (t *Tree) Root() []byte {
+	return t.smt.Root()
+}
+
+// buildProof walks index up to the root, returning the ordered sibling
+// hashes and the matching left/right bit vector (bits[i] true means the
+// leaf-side hash at level i is the right child of its parent).
+func (t *Tree) buildProof(index int64) (siblings [][]byte, bits []bool, err error) {
+	proof, err := t.smt.GetProof(uint64(index))
+	if err != nil {
+		return nil, nil, err
+	}
+	siblings = make([][]byte, len(proof.MerkleProof))
+	bits = make([]bool, len(proof.MerkleProof))
+	copy(siblings, proof.MerkleProof)
+	position := uint64(index)
+	for level := range siblings {
+		bits[level] = position&1 == 1
+		position >>= 1
+	}
+	return siblings, bits, nil
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package util
+
+import "fmt"
+
+// CacheInvalidationChannel is the Redis pub/sub channel the reorg
+// subscriber listens on for sets of (accountIndex, assetId) pairs to
+// evict from the cache.
+const CacheInvalidationChannel = "cache:invalidate"
+
+// GetAccountEpochKey is the Redis key holding the monotonically-increasing
+// epoch stamped alongside accountIndex's cached nonce. It is bumped only
+// for this accountIndex by the reorg subscriber when a rollback
+// invalidates it, so a reader can tell its cached value predates the
+// rollback without that epoch bump affecting any other account's cache.
+func GetAccountEpochKey(accountIndex int64) string {
+	return fmt.Sprintf("cache:epoch:account:%d", accountIndex)
+}
+
+// GetAccountAssetEpochKey is the Redis key holding the monotonically-
+// increasing epoch stamped alongside the cached balance for
+// (accountIndex, assetId). It is bumped only for this pair by the reorg
+// subscriber when a rollback invalidates it.
+func GetAccountAssetEpochKey(accountIndex, assetId int64) string {
+	return fmt.Sprintf("cache:epoch:asset:%d:%d", accountIndex, assetId)
+}
@@ -56,13 +56,26 @@ func GetLatestAccountInfo(
 	}
 	// get latest nonce
 	key := util.GetAccountKey(accountIndex)
+	stateEpoch, err := accountEpoch(svcCtx, accountIndex)
+	if err != nil {
+		logx.Errorf("[GetLatestAccountInfoByLock] unable to get current account epoch: %s", err.Error())
+		return nil, err
+	}
 	// get nonce from redis first
-	nonceStr, err := svcCtx.RedisConnection.Get(key)
+	cached, err := svcCtx.RedisConnection.Get(key)
 	if err != nil {
 		logx.Errorf("[GetLatestAccountInfoByLock] unable to get from redis: %s", err.Error())
 		return nil, err
 	}
-	if nonceStr != "" {
+	nonceStr, cachedEpoch, decodeErr := "", int64(0), error(nil)
+	if cached != "" {
+		nonceStr, cachedEpoch, decodeErr = decodeEpochValue(cached)
+		if decodeErr != nil {
+			logx.Errorf("[GetLatestAccountInfoByLock] unable to decode cached value: %s", decodeErr.Error())
+			return nil, decodeErr
+		}
+	}
+	if nonceStr != "" && cachedEpoch >= stateEpoch {
 		accountInfo.Nonce, err = strconv.ParseInt(nonceStr, 10, 64)
 		if err != nil {
 			logx.Errorf("[GetLatestAccountInfoByLock] unable to parse int: %s", err.Error())
@@ -93,7 +106,7 @@ func GetLatestAccountInfo(
 		accountInfo.Nonce = l2MempoolTx.Nonce
 		// update redis
 		if err == nil {
-			svcCtx.RedisConnection.Setex(key, strconv.FormatInt(accountInfo.Nonce, 10), BalanceExpiryTime)
+			svcCtx.RedisConnection.Setex(key, encodeEpochValue(strconv.FormatInt(accountInfo.Nonce, 10), stateEpoch), BalanceExpiryTime)
 			redisLock.Release()
 		}
 	}
@@ -115,13 +128,26 @@ func GetLatestAsset(
 	// get latest account info by accountIndex and assetId
 	key := util.GetAccountAssetUniqueKey(accountIndex, assetId)
 	lockKey := util.GetLockKey(key)
+	stateEpoch, err := assetEpoch(svcCtx, accountIndex, assetId)
+	if err != nil {
+		logx.Errorf("[GetLatestAssetByLock] unable to get current asset epoch: %s", err.Error())
+		return nil, err
+	}
 	// get data from redis
-	latestBalance, err := svcCtx.RedisConnection.Get(key)
+	cached, err := svcCtx.RedisConnection.Get(key)
 	if err != nil {
 		logx.Errorf("[GetLatestAssetByLock] unable to get balance from redis: %s", err.Error())
 		return nil, err
 	}
-	if latestBalance != "" {
+	latestBalance, cachedEpoch, decodeErr := "", int64(0), error(nil)
+	if cached != "" {
+		latestBalance, cachedEpoch, decodeErr = decodeEpochValue(cached)
+		if decodeErr != nil {
+			logx.Errorf("[GetLatestAssetByLock] unable to decode cached value: %s", decodeErr.Error())
+			return nil, decodeErr
+		}
+	}
+	if latestBalance != "" && cachedEpoch >= stateEpoch {
 		assetInfo.Balance = latestBalance
 	} else {
 		// get lock
@@ -186,7 +212,7 @@ func GetLatestAsset(
 			assetInfo.Balance = latestBalance
 		}
 		if tryLockErr == nil {
-			svcCtx.RedisConnection.Setex(key, assetInfo.Balance, BalanceExpiryTime)
+			svcCtx.RedisConnection.Setex(key, encodeEpochValue(assetInfo.Balance, stateEpoch), BalanceExpiryTime)
 			redisLock.Release()
 		}
 	}
@@ -0,0 +1,76 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package globalmapHandler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/zecrey-labs/zecrey-legend/common/util"
+	"github.com/zecrey-labs/zecrey-legend/service/rpc/globalRPC/internal/svc"
+)
+
+const epochValueSeparator = "#"
+
+// accountEpoch returns the epoch the reorg subscriber last bumped
+// accountIndex's own epoch key to. A missing key means this account has
+// never been rolled back, so epoch 0 is treated as the starting point.
+// Unlike a single global epoch, bumping this key only affects readers of
+// this one account.
+func accountEpoch(svcCtx *svc.ServiceContext, accountIndex int64) (int64, error) {
+	return readEpoch(svcCtx, util.GetAccountEpochKey(accountIndex))
+}
+
+// assetEpoch is accountEpoch's counterpart for a single (accountIndex,
+// assetId) balance cache entry.
+func assetEpoch(svcCtx *svc.ServiceContext, accountIndex, assetId int64) (int64, error) {
+	return readEpoch(svcCtx, util.GetAccountAssetEpochKey(accountIndex, assetId))
+}
+
+func readEpoch(svcCtx *svc.ServiceContext, key string) (int64, error) {
+	raw, err := svcCtx.RedisConnection.Get(key)
+	if err != nil {
+		return 0, err
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(raw, 10, 64)
+}
+
+// encodeEpochValue stamps value with the epoch that was current when it
+// was cached, so a reader can tell the value predates a later rollback
+// even while its TTL is still running.
+func encodeEpochValue(value string, epoch int64) string {
+	return value + epochValueSeparator + strconv.FormatInt(epoch, 10)
+}
+
+// decodeEpochValue splits a value written by encodeEpochValue back into
+// the cached value and the epoch it was stamped with.
+func decodeEpochValue(raw string) (value string, epoch int64, err error) {
+	idx := strings.LastIndex(raw, epochValueSeparator)
+	if idx < 0 {
+		return "", 0, errors.New("cached value missing stateEpoch stamp")
+	}
+	epoch, err = strconv.ParseInt(raw[idx+1:], 10, 64)
+	if err != nil {
+		return "", 0, err
+	}
+	return raw[:idx], epoch, nil
+}
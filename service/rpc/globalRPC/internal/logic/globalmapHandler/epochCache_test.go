@@ -0,0 +1,37 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package globalmapHandler
+
+import "testing"
+
+func TestEncodeDecodeEpochValueRoundTrip(t *testing.T) {
+	raw := encodeEpochValue("12345", 7)
+	value, epoch, err := decodeEpochValue(raw)
+	if err != nil {
+		t.Fatalf("unable to decode: %s", err.Error())
+	}
+	if value != "12345" || epoch != 7 {
+		t.Fatalf("round trip mismatch: got (%q, %d), want (\"12345\", 7)", value, epoch)
+	}
+}
+
+func TestDecodeEpochValueRejectsMissingStamp(t *testing.T) {
+	if _, _, err := decodeEpochValue("12345"); err == nil {
+		t.Fatal("expected an error for a value with no epoch stamp")
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package globalmapHandler
+
+import (
+	"encoding/json"
+
+	"github.com/zecrey-labs/zecrey-legend/common/util"
+	"github.com/zecrey-labs/zecrey-legend/service/rpc/globalRPC/internal/svc"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Invalidation identifies a single cached account/asset entry that must be
+// evicted because the block/mempool tx that produced it was rolled back.
+type Invalidation struct {
+	AccountIndex int64
+	AssetId      int64
+}
+
+// ReorgSubscriber listens for invalidations published by the chain
+// follower when it detects an L1 reorg or a rolled-back L2 mempool tx.
+// For each invalidated (accountIndex, assetId) pair it bumps that pair's
+// own epoch key so any cache entry written before the rollback is treated
+// as stale even though its TTL hasn't expired yet, then deletes the
+// affected keys outright. Only the rolled-back account/asset pairs are
+// touched; every other account's cache stays live.
+type ReorgSubscriber struct {
+	svcCtx *svc.ServiceContext
+}
+
+// NewReorgSubscriber builds a ReorgSubscriber for svcCtx. Call Run once at
+// service start; it subscribes for the lifetime of the process.
+func NewReorgSubscriber(svcCtx *svc.ServiceContext) *ReorgSubscriber {
+	return &ReorgSubscriber{svcCtx: svcCtx}
+}
+
+// Run subscribes to util.CacheInvalidationChannel and blocks handling
+// messages until the subscription errors out or the process exits.
+func (s *ReorgSubscriber) Run() error {
+	sub, err := s.svcCtx.RedisConnection.Subscribe(util.CacheInvalidationChannel)
+	if err != nil {
+		logx.Errorf("[ReorgSubscriber] unable to subscribe to %s: %s", util.CacheInvalidationChannel, err.Error())
+		return err
+	}
+	for message := range sub.Channel() {
+		var invalidations []Invalidation
+		if err := json.Unmarshal([]byte(message), &invalidations); err != nil {
+			logx.Errorf("[ReorgSubscriber] unable to parse invalidation message: %s", err.Error())
+			continue
+		}
+		s.handle(invalidations)
+	}
+	return nil
+}
+
+func (s *ReorgSubscriber) handle(invalidations []Invalidation) {
+	for _, invalidation := range invalidations {
+		if _, err := s.svcCtx.RedisConnection.Incr(util.GetAccountEpochKey(invalidation.AccountIndex)); err != nil {
+			logx.Errorf("[ReorgSubscriber] unable to bump account epoch for %d: %s",
+				invalidation.AccountIndex, err.Error())
+		}
+		if _, err := s.svcCtx.RedisConnection.Incr(util.GetAccountAssetEpochKey(invalidation.AccountIndex, invalidation.AssetId)); err != nil {
+			logx.Errorf("[ReorgSubscriber] unable to bump asset epoch for %d/%d: %s",
+				invalidation.AccountIndex, invalidation.AssetId, err.Error())
+		}
+		if err := s.svcCtx.RedisConnection.Del(util.GetAccountKey(invalidation.AccountIndex)); err != nil {
+			logx.Errorf("[ReorgSubscriber] unable to delete account cache for %d: %s",
+				invalidation.AccountIndex, err.Error())
+		}
+		if err := s.svcCtx.RedisConnection.Del(util.GetAccountAssetUniqueKey(invalidation.AccountIndex, invalidation.AssetId)); err != nil {
+			logx.Errorf("[ReorgSubscriber] unable to delete asset cache for %d/%d: %s",
+				invalidation.AccountIndex, invalidation.AssetId, err.Error())
+		}
+	}
+}
+
+// PublishInvalidations is called by the reorg-detecting side (mirroring
+// the reorganization-tracking pattern used by chain-following wallets)
+// once it has rolled back to a given block, so every globalRPC instance
+// subscribed on util.CacheInvalidationChannel evicts the affected keys.
+func PublishInvalidations(svcCtx *svc.ServiceContext, invalidations []Invalidation) error {
+	payload, err := json.Marshal(invalidations)
+	if err != nil {
+		logx.Errorf("[PublishInvalidations] unable to marshal invalidations: %s", err.Error())
+		return err
+	}
+	_, err = svcCtx.RedisConnection.Publish(util.CacheInvalidationChannel, string(payload))
+	if err != nil {
+		logx.Errorf("[PublishInvalidations] unable to publish invalidations: %s", err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package globalmapHandler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/zecrey-labs/zecrey-legend/common/commonTx"
+	"github.com/zecrey-labs/zecrey-legend/common/model/account"
+	"github.com/zecrey-labs/zecrey-legend/common/model/asset"
+	"github.com/zecrey-labs/zecrey-legend/common/model/proposal"
+	"github.com/zecrey-labs/zecrey-legend/common/tree"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ValidateVoteTxWeight must run wherever a VoteTx is admitted to the
+// mempool; that generic tx-submission entrypoint isn't part of this
+// trimmed tree (no tx type, this one included, has one here), so the
+// caller wiring this in is left to the committer's existing admission
+// path. It never trusts the tx's own claims about the snapshot: it looks
+// up the proposal's pinned SnapshotRoot from proposalModel, rejects the
+// tx outright if txInfo.SnapshotRoot doesn't match that pinned root, then
+// independently rebuilds the snapshotted governance-token balance tree
+// from asset history at the proposal's snapshot block height and confirms
+// the voter's own leaf sits under it before checking the declared weight
+// against that balance. A vote cannot claim more voting power than the
+// voter actually held when the proposal opened, and it can't launder an
+// arbitrary self-consistent root through SnapshotRoot to get there.
+func ValidateVoteTxWeight(
+	accountHistoryModel account.AccountHistoryModel,
+	assetHistoryModel asset.AssetHistoryModel,
+	proposalModel proposal.ProposalModel,
+	txInfo *commonTx.VoteTxInfo,
+) error {
+	weight, ok := new(big.Int).SetString(txInfo.Weight, 10)
+	if !ok {
+		errInfo := fmt.Sprintf("[ValidateVoteTxWeight] invalid weight %s", txInfo.Weight)
+		logx.Error(errInfo)
+		return errors.New(errInfo)
+	}
+
+	proposalInfo, err := proposalModel.GetProposalByProposalId(txInfo.ProposalId)
+	if err != nil {
+		logx.Errorf("[ValidateVoteTxWeight] unable to get proposal %d: %s", txInfo.ProposalId, err.Error())
+		return err
+	}
+	if !bytes.Equal(txInfo.SnapshotRoot, proposalInfo.SnapshotRoot) {
+		errInfo := fmt.Sprintf("[ValidateVoteTxWeight] tx snapshot root does not match proposal %d's pinned snapshot root",
+			txInfo.ProposalId)
+		logx.Error(errInfo)
+		return errors.New(errInfo)
+	}
+
+	assetHistories, err := assetHistoryModel.GetAssetsByAccountIndexAndBlockHeight(txInfo.AccountIndex, proposalInfo.SnapshotBlockHeight)
+	if err != nil {
+		logx.Errorf("[ValidateVoteTxWeight] unable to get snapshotted balances for account %d: %s",
+			txInfo.AccountIndex, err.Error())
+		return err
+	}
+	snapshotBalance := "0"
+	for _, assetHistory := range assetHistories {
+		if assetHistory.AssetId == proposalInfo.AssetId {
+			snapshotBalance = assetHistory.Balance
+			break
+		}
+	}
+	balance, ok := new(big.Int).SetString(snapshotBalance, 10)
+	if !ok {
+		errInfo := fmt.Sprintf("[ValidateVoteTxWeight] invalid snapshotted balance %s for account %d",
+			snapshotBalance, txInfo.AccountIndex)
+		logx.Error(errInfo)
+		return errors.New(errInfo)
+	}
+	if weight.Cmp(balance) > 0 {
+		errInfo := fmt.Sprintf("[ValidateVoteTxWeight] declared weight %s exceeds snapshotted balance %s for account %d",
+			txInfo.Weight, snapshotBalance, txInfo.AccountIndex)
+		logx.Error(errInfo)
+		return errors.New(errInfo)
+	}
+
+	snapshotTree, err := tree.NewGovernanceSnapshotTree(accountHistoryModel, assetHistoryModel, proposalInfo.AssetId, proposalInfo.SnapshotBlockHeight)
+	if err != nil {
+		logx.Errorf("[ValidateVoteTxWeight] unable to rebuild snapshot tree for proposal %d: %s",
+			txInfo.ProposalId, err.Error())
+		return err
+	}
+	siblings, bits, err := tree.GenerateAccountInclusionProof(snapshotTree, txInfo.AccountIndex)
+	if err != nil {
+		logx.Errorf("[ValidateVoteTxWeight] unable to build inclusion proof for account %d: %s",
+			txInfo.AccountIndex, err.Error())
+		return err
+	}
+	leafHash, err := tree.ComputeAccountAssetLeafHash(snapshotBalance, "0")
+	if err != nil {
+		logx.Errorf("[ValidateVoteTxWeight] unable to compute leaf hash: %s", err.Error())
+		return err
+	}
+	valid, err := tree.VerifyInclusionProof(leafHash, siblings, bits, proposalInfo.SnapshotRoot)
+	if err != nil {
+		logx.Errorf("[ValidateVoteTxWeight] unable to verify inclusion proof: %s", err.Error())
+		return err
+	}
+	if !valid {
+		errInfo := fmt.Sprintf("[ValidateVoteTxWeight] rebuilt balance leaf for account %d does not sit under proposal %d's snapshot root",
+			txInfo.AccountIndex, txInfo.ProposalId)
+		logx.Error(errInfo)
+		return errors.New(errInfo)
+	}
+	return nil
+}
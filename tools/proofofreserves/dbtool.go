@@ -0,0 +1,55 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"bufio"
+	"os"
+
+	"github.com/zecrey-labs/zecrey-legend/common/proofofreserves"
+)
+
+// runDbTool dumps the (accountIndex, assetId, balance, leafHash,
+// siblingPath) snapshot table at --height to the file named --out, one row
+// per line, for consumption by the prover and userproof commands.
+func runDbTool(args []string) {
+	fs := newFlagSet("dbtool")
+	height := fs.Int64("height", 0, "block height to snapshot")
+	out := fs.String("out", "snapshot.tsv", "output file for the snapshot table")
+	fs.Parse(args)
+
+	deps, err := newDeps(*height)
+	exitOnErr("dbtool", err)
+
+	rows, err := proofofreserves.DumpSnapshot(
+		deps.accountHistoryModel, deps.assetHistoryModel, deps.assetProof, deps.accountProof, *height,
+	)
+	exitOnErr("dbtool", err)
+
+	file, err := os.Create(*out)
+	exitOnErr("dbtool", err)
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	defer writer.Flush()
+	for _, row := range rows {
+		if _, err := writer.WriteString(proofofreserves.FormatRow(row) + "\n"); err != nil {
+			exitOnErr("dbtool", err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+
+	"github.com/zecrey-labs/zecrey-legend/common/model/account"
+	"github.com/zecrey-labs/zecrey-legend/common/model/asset"
+	"github.com/zecrey-labs/zecrey-legend/common/proofofreserves"
+	"github.com/zecrey-labs/zecrey-legend/common/tree"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+// Config mirrors the DSN-only slice of the service configs used by the
+// other zecrey-legend services; the proof-of-reserves tools only ever
+// need read access to the account/asset history tables.
+type Config struct {
+	DataSource string
+}
+
+// deps bundles everything the dbtool command needs to read account state
+// and walk the committed account/asset trees.
+type deps struct {
+	accountHistoryModel account.AccountHistoryModel
+	assetHistoryModel   asset.AssetHistoryModel
+	accountTree         *tree.Tree
+	assetTrees          map[int64]*tree.Tree
+	accountProof        proofofreserves.AccountProofProvider
+	assetProof          proofofreserves.AssetProofProvider
+}
+
+func newDeps(blockHeight int64) (*deps, error) {
+	var c Config
+	conf.MustLoad("etc/proofofreserves.yaml", &c)
+
+	conn := sqlx.NewMysql(c.DataSource)
+	accountHistoryModel := account.NewAccountHistoryModel(conn)
+	assetHistoryModel := asset.NewAssetHistoryModel(conn)
+
+	accountTree, assetTrees, err := tree.NewAccountTree(accountHistoryModel, assetHistoryModel, blockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deps{
+		accountHistoryModel: accountHistoryModel,
+		assetHistoryModel:   assetHistoryModel,
+		accountTree:         accountTree,
+		assetTrees:          assetTrees,
+		accountProof: func(accountIndex int64) ([][]byte, []bool, error) {
+			return tree.GenerateAccountInclusionProof(accountTree, accountIndex)
+		},
+		assetProof: func(accountIndex, assetId int64) ([][]byte, []bool, error) {
+			assetTree, ok := assetTrees[accountIndex]
+			if !ok {
+				return nil, nil, errors.New("no asset tree for account")
+			}
+			return tree.GenerateAccountAssetInclusionProof(assetTree, accountIndex, assetId)
+		},
+	}, nil
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import "github.com/zecrey-labs/zecrey-legend/common/proofofreserves"
+
+// runKeyGen compiles the reserve circuit and runs the Groth16 trusted
+// setup, writing the proving/verifying keys to --pk/--vk.
+func runKeyGen(args []string) {
+	fs := newFlagSet("keygen")
+	pk := fs.String("pk", "proving.key", "output path for the proving key")
+	vk := fs.String("vk", "verifying.key", "output path for the verifying key")
+	fs.Parse(args)
+
+	exitOnErr("keygen", proofofreserves.KeyGen(*pk, *vk))
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Command proofofreserves builds and verifies zkBNB's proof-of-reserves
+// attestations. It has four subcommands:
+//
+//	dbtool    dump the account/asset snapshot table at a fixed block height
+//	keygen    compile the reserve circuit and run the Groth16 trusted setup
+//	prover    shard the snapshot into groups and prove each group in parallel
+//	userproof emit a single user's inclusion proof against a proven group
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "dbtool":
+		runDbTool(os.Args[2:])
+	case "keygen":
+		runKeyGen(os.Args[2:])
+	case "prover":
+		runProver(os.Args[2:])
+	case "userproof":
+		runUserProof(os.Args[2:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: proofofreserves <dbtool|keygen|prover|userproof> [flags]")
+}
+
+func exitOnErr(cmd string, err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", cmd, err.Error())
+		os.Exit(1)
+	}
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ExitOnError)
+}
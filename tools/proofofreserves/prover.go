@@ -0,0 +1,76 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zecrey-labs/zecrey-legend/common/proofofreserves"
+)
+
+// runProver reads the snapshot table produced by dbtool, shards it into
+// groups of proofofreserves.GroupSize, and generates a Groth16 proof per
+// group in parallel, writing one proof file per group to --out.
+func runProver(args []string) {
+	fs := newFlagSet("prover")
+	snapshotPath := fs.String("snapshot", "snapshot.tsv", "snapshot table produced by dbtool")
+	pk := fs.String("pk", "proving.key", "proving key produced by keygen")
+	stateRoot := fs.String("state-root", "", "hex-encoded committed state root")
+	reserves := fs.String("reserves", "", "comma-separated total reserves, one per assetId 0..NumAssets-1")
+	out := fs.String("out", ".", "output directory for group proofs")
+	fs.Parse(args)
+
+	rows, err := readSnapshot(*snapshotPath)
+	exitOnErr("prover", err)
+
+	var totalReserves [proofofreserves.NumAssets]string
+	for i, amount := range strings.Split(*reserves, ",") {
+		if i >= proofofreserves.NumAssets {
+			break
+		}
+		totalReserves[i] = amount
+	}
+
+	groups, err := proofofreserves.Prove(*pk, rows, common.FromHex(*stateRoot), totalReserves, *out)
+	exitOnErr("prover", err)
+
+	fmt.Fprintf(os.Stderr, "generated %d group proof(s)\n", len(groups))
+}
+
+func readSnapshot(path string) ([]*proofofreserves.AccountLeafSnapshot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rows []*proofofreserves.AccountLeafSnapshot
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		row, err := proofofreserves.ParseRow(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/zecrey-labs/zecrey-legend/common/proofofreserves"
+)
+
+// runUserProof rebuilds the group a given account/asset pair belongs to
+// from the snapshot table and the group's proof file, then writes the
+// per-user JSON artifact described by proofofreserves.UserProof to --out.
+func runUserProof(args []string) {
+	fs := newFlagSet("userproof")
+	snapshotPath := fs.String("snapshot", "snapshot.tsv", "snapshot table produced by dbtool")
+	proofDir := fs.String("proof-dir", ".", "directory containing group proofs produced by prover")
+	accountIndex := fs.Int64("account-index", 0, "account index to prove inclusion for")
+	assetId := fs.Int64("asset-id", 0, "asset id to prove inclusion for")
+	out := fs.String("out", "user.json", "output path for the user proof JSON")
+	fs.Parse(args)
+
+	rows, err := readSnapshot(*snapshotPath)
+	exitOnErr("userproof", err)
+
+	shards := proofofreserves.ShardSnapshot(rows)
+	for groupId, shard := range shards {
+		found := false
+		for _, row := range shard {
+			if row.AccountIndex == *accountIndex && row.AssetId == *assetId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		proof := groth16.NewProof(ecc.BN254)
+		proofFile, err := os.Open(fmt.Sprintf("%s/group-%d.proof", *proofDir, groupId))
+		exitOnErr("userproof", err)
+		_, err = proof.ReadFrom(proofFile)
+		proofFile.Close()
+		exitOnErr("userproof", err)
+
+		userProof, err := proofofreserves.BuildUserProof(
+			&proofofreserves.Group{Id: groupId, Rows: shard, Proof: proof},
+			*accountIndex, *assetId,
+		)
+		exitOnErr("userproof", err)
+
+		data, err := proofofreserves.MarshalUserProof(userProof)
+		exitOnErr("userproof", err)
+		exitOnErr("userproof", os.WriteFile(*out, data, 0644))
+		return
+	}
+
+	exitOnErr("userproof", fmt.Errorf("account %d asset %d not found in snapshot", *accountIndex, *assetId))
+}